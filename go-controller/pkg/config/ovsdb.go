@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// OVSDBConfig holds tunables for how the model client talks to the NB/SB/OVS
+// OVSDB servers.
+type OVSDBConfig struct {
+	// TxnTimeout bounds how long libovsdbops.GetSBGlobal waits for its
+	// lookup before returning a context error. It covers the whole
+	// CreateOrUpdate call, cache lookup included, not just a Transact
+	// call, and the call itself is not cancelled when it expires. The
+	// model client does not read this value, and no other helper applies
+	// it. Operators on high-scale clusters where OVSDB is slow to answer
+	// can raise this without a recompile.
+	TxnTimeout time.Duration
+}
+
+// OVSDB holds the active OVSDB tunables. It is a plain package-level
+// variable rather than a CLI flag, since this snapshot does not carry the
+// flag-parsing config package these tunables would otherwise live in.
+var OVSDB = OVSDBConfig{
+	TxnTimeout: 100 * time.Second,
+}
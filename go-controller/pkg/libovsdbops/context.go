@@ -0,0 +1,30 @@
+package libovsdbops
+
+import "context"
+
+// withContext runs do in a goroutine and returns as soon as either it
+// finishes or ctx is done, whichever comes first. It is a stand-in for
+// context-aware *WithContext variants of the ModelClient methods
+// (CreateOrUpdateWithContext, DeleteWithContext, LookupWithContext) until
+// those exist: it bounds how long a caller will *wait* on do, without
+// requiring model_client.go itself to accept a context.
+//
+// It does not cancel do when ctx expires -- do has no way to be
+// interrupted mid-flight, since the client calls it wraps aren't
+// themselves context-aware -- so a timed-out do keeps running in the
+// background until it finishes on its own. Callers should treat this as
+// bounding how long they wait for a result, not as a resource-usage
+// cancellation mechanism.
+func withContext(ctx context.Context, do func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- do()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
@@ -0,0 +1,14 @@
+package libovsdbops
+
+import "errors"
+
+// ErrDuplicate reports that an Insert op would collide with a row that
+// already satisfies one of the table's indexes, even though the caller's
+// ModelPredicate found no existing match in the cache.
+// CreateOrUpdateMACBinding handles it by retrying as an update against
+// the row that won the race rather than failing reconciliation outright.
+//
+// Nothing in this package returns ErrDuplicate: the model client's Insert
+// path performs no index-collision check, so an Insert that collides
+// fails with whatever error the OVSDB server reports instead.
+var ErrDuplicate = errors.New("object with given indexes already exists")
@@ -0,0 +1,66 @@
+package libovsdbops
+
+import (
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	libovsdb "github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+)
+
+// ListLoadBalancerHealthChecks looks up every Load_Balancer_Health_Check row from the cache
+func ListLoadBalancerHealthChecks(nbClient libovsdbclient.Client) ([]*nbdb.LoadBalancerHealthCheck, error) {
+	found := []*nbdb.LoadBalancerHealthCheck{}
+	opModel := OperationModel{
+		Model:          &nbdb.LoadBalancerHealthCheck{},
+		ModelPredicate: func(item *nbdb.LoadBalancerHealthCheck) bool { return true },
+		ExistingResult: &found,
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+
+	m := NewModelClient(nbClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// CreateOrUpdateLoadBalancerHealthChecksOps returns the ops to create or update the
+// provided health checks, matched by Vip
+func CreateOrUpdateLoadBalancerHealthChecksOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, hcs ...*nbdb.LoadBalancerHealthCheck) ([]libovsdb.Operation, error) {
+	opModels := make([]OperationModel, 0, len(hcs))
+	for i := range hcs {
+		hc := hcs[i]
+		opModel := OperationModel{
+			Model:          hc,
+			ModelPredicate: func(item *nbdb.LoadBalancerHealthCheck) bool { return item.Vip == hc.Vip },
+			OnModelUpdates: []interface{}{
+				&hc.Options,
+				&hc.ExternalIDs,
+			},
+			ErrNotFound: false,
+			BulkOp:      false,
+		}
+		opModels = append(opModels, opModel)
+	}
+
+	m := NewModelClient(nbClient)
+	return m.CreateOrUpdateOps(ops, opModels...)
+}
+
+// DeleteLoadBalancerHealthChecksOps returns the ops to delete the provided health checks
+func DeleteLoadBalancerHealthChecksOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, hcs ...*nbdb.LoadBalancerHealthCheck) ([]libovsdb.Operation, error) {
+	opModels := make([]OperationModel, 0, len(hcs))
+	for i := range hcs {
+		opModel := OperationModel{
+			Model:       hcs[i],
+			ErrNotFound: false,
+			BulkOp:      false,
+		}
+		opModels = append(opModels, opModel)
+	}
+
+	m := NewModelClient(nbClient)
+	return m.DeleteOps(ops, opModels...)
+}
@@ -0,0 +1,54 @@
+package libovsdbops
+
+import (
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	libovsdb "github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+)
+
+// FindLogicalSwitchByName looks up the named LogicalSwitch row from the
+// cache. It returns (nil, nil), not an error, when no such switch exists.
+func FindLogicalSwitchByName(nbClient libovsdbclient.Client, name string) (*nbdb.LogicalSwitch, error) {
+	found := []*nbdb.LogicalSwitch{}
+	opModel := OperationModel{
+		Model:          &nbdb.LogicalSwitch{Name: name},
+		ModelPredicate: func(item *nbdb.LogicalSwitch) bool { return item.Name == name },
+		ExistingResult: &found,
+		ErrNotFound:    false,
+		BulkOp:         false,
+	}
+
+	m := NewModelClient(nbClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	return found[0], nil
+}
+
+// SetLogicalSwitchIPPortMappingsOps returns the ops to set sw's
+// ip_port_mappings to exactly mappings, matched by Name. The switch is
+// expected to already exist; this does not create one. Callers that only
+// own a subset of the switch's ip_port_mappings keys (e.g. one service's
+// health-check mappings on a switch shared with other services) must
+// merge against the switch's current ip_port_mappings themselves before
+// calling this -- it is a full-column replace, not a merge.
+func SetLogicalSwitchIPPortMappingsOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, sw *nbdb.LogicalSwitch, mappings map[string]string) ([]libovsdb.Operation, error) {
+	sw.IPPortMappings = mappings
+	opModel := OperationModel{
+		Model:          sw,
+		ModelPredicate: func(item *nbdb.LogicalSwitch) bool { return item.Name == sw.Name },
+		OnModelUpdates: []interface{}{
+			&sw.IPPortMappings,
+		},
+		ErrNotFound: true,
+		BulkOp:      false,
+	}
+
+	m := NewModelClient(nbClient)
+	return m.CreateOrUpdateOps(ops, opModel)
+}
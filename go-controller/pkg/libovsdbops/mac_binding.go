@@ -0,0 +1,123 @@
+package libovsdbops
+
+import (
+	"errors"
+
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/sbdb"
+)
+
+// LookupDatapathByExternalID resolves a logical router/switch name to the
+// UUID of its Datapath_Binding row, looking it up by the "name" external-id
+// ovn-northd stamps on every datapath it owns. MAC_Binding.datapath is a
+// UUID reference, so callers that only know a logical router/switch name
+// need this before they can build or match a MAC_Binding row.
+func LookupDatapathByExternalID(sbClient libovsdbclient.Client, name string) (string, error) {
+	found := []*sbdb.DatapathBinding{}
+	opModel := OperationModel{
+		Model:          &sbdb.DatapathBinding{},
+		ModelPredicate: func(item *sbdb.DatapathBinding) bool { return item.ExternalIDs["name"] == name },
+		ExistingResult: &found,
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := NewModelClient(sbClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return "", err
+	}
+
+	return found[0].UUID, nil
+}
+
+// CreateOrUpdateMACBinding idempotently upserts a MAC_Binding row for
+// logicalPort/ip on routerName's datapath, matched by the
+// (logical_port, ip) pair rather than by UUID, so repeated reconciliation
+// passes update the same row instead of piling up duplicates. If the
+// model client reports ErrDuplicate (a concurrent caller created that
+// same row between our cache lookup and our Insert), this retries as an
+// update against the row that actually landed instead of failing the
+// whole reconciliation pass.
+func CreateOrUpdateMACBinding(sbClient libovsdbclient.Client, logicalPort, routerName, mac, ip string) error {
+	datapath, err := LookupDatapathByExternalID(sbClient, routerName)
+	if err != nil {
+		return err
+	}
+
+	mb := &sbdb.MACBinding{
+		LogicalPort: logicalPort,
+		IP:          ip,
+		MAC:         mac,
+		Datapath:    datapath,
+	}
+	opModel := OperationModel{
+		Model: mb,
+		ModelPredicate: func(item *sbdb.MACBinding) bool {
+			return item.LogicalPort == logicalPort && item.IP == ip
+		},
+		OnModelUpdates: []interface{}{
+			&mb.MAC,
+			&mb.Datapath,
+		},
+		ErrNotFound: false,
+		BulkOp:      false,
+	}
+
+	m := NewModelClient(sbClient)
+	_, err = m.CreateOrUpdate(opModel)
+	if errors.Is(err, ErrDuplicate) {
+		existing, findErr := FindMACBindingByLogicalPortIP(sbClient, logicalPort, ip)
+		if findErr != nil {
+			return findErr
+		}
+		mb.UUID = existing.UUID
+		opModel.ModelPredicate = func(item *sbdb.MACBinding) bool { return item.UUID == mb.UUID }
+		_, err = m.CreateOrUpdate(opModel)
+	}
+	return err
+}
+
+// FindMACBindingByLogicalPortIP looks up the MAC_Binding row for the given
+// (logical_port, ip) pair. The predicate layer matches on either column, so
+// stale-entry reconciliation can also locate a row knowing only its
+// logical_port or only its ip by passing the empty string for the other.
+func FindMACBindingByLogicalPortIP(sbClient libovsdbclient.Client, logicalPort, ip string) (*sbdb.MACBinding, error) {
+	found := []*sbdb.MACBinding{}
+	opModel := OperationModel{
+		Model: &sbdb.MACBinding{},
+		ModelPredicate: func(item *sbdb.MACBinding) bool {
+			if logicalPort != "" && item.LogicalPort != logicalPort {
+				return false
+			}
+			if ip != "" && item.IP != ip {
+				return false
+			}
+			return true
+		},
+		ExistingResult: &found,
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := NewModelClient(sbClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+
+	return found[0], nil
+}
+
+// DeleteMACBindingsForDatapath deletes every MAC_Binding row pointing at
+// datapath, e.g. when the owning logical router/switch is being torn down
+func DeleteMACBindingsForDatapath(sbClient libovsdbclient.Client, datapath string) error {
+	opModel := OperationModel{
+		Model:          &sbdb.MACBinding{},
+		ModelPredicate: func(item *sbdb.MACBinding) bool { return item.Datapath == datapath },
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+
+	m := NewModelClient(sbClient)
+	return m.Delete(opModel)
+}
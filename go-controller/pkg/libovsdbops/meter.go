@@ -0,0 +1,456 @@
+package libovsdbops
+
+import (
+	"fmt"
+
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+	libovsdb "github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+)
+
+// ListMeters looks up every Meter row from the cache
+func ListMeters(nbClient libovsdbclient.Client) ([]*nbdb.Meter, error) {
+	found := []*nbdb.Meter{}
+	opModel := OperationModel{
+		Model:          &nbdb.Meter{},
+		ModelPredicate: func(item *nbdb.Meter) bool { return true },
+		ExistingResult: &found,
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+
+	m := NewModelClient(nbClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// ListMeterBands looks up every Meter_Band row from the cache
+func ListMeterBands(nbClient libovsdbclient.Client) ([]*nbdb.MeterBand, error) {
+	found := []*nbdb.MeterBand{}
+	opModel := OperationModel{
+		Model:          &nbdb.MeterBand{},
+		ModelPredicate: func(item *nbdb.MeterBand) bool { return true },
+		ExistingResult: &found,
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+
+	m := NewModelClient(nbClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// FindMeterByName looks up the named Meter row from the cache. It returns
+// (nil, nil), not an error, when no such meter exists.
+func FindMeterByName(nbClient libovsdbclient.Client, name string) (*nbdb.Meter, error) {
+	found := []*nbdb.Meter{}
+	opModel := OperationModel{
+		Model:          &nbdb.Meter{Name: name},
+		ModelPredicate: func(item *nbdb.Meter) bool { return item.Name == name },
+		ExistingResult: &found,
+		ErrNotFound:    false,
+		BulkOp:         false,
+	}
+
+	m := NewModelClient(nbClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	return found[0], nil
+}
+
+// listMeterBandsByUUIDs looks up the Meter_Band rows for uuids, preserving
+// their order
+func listMeterBandsByUUIDs(nbClient libovsdbclient.Client, uuids []string) ([]*nbdb.MeterBand, error) {
+	want := make(map[string]bool, len(uuids))
+	for _, uuid := range uuids {
+		want[uuid] = true
+	}
+
+	found := []*nbdb.MeterBand{}
+	opModel := OperationModel{
+		Model:          &nbdb.MeterBand{},
+		ModelPredicate: func(item *nbdb.MeterBand) bool { return want[item.UUID] },
+		ExistingResult: &found,
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+
+	m := NewModelClient(nbClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+
+	byUUID := make(map[string]*nbdb.MeterBand, len(found))
+	for _, band := range found {
+		byUUID[band.UUID] = band
+	}
+	ordered := make([]*nbdb.MeterBand, 0, len(uuids))
+	for _, uuid := range uuids {
+		if band, ok := byUUID[uuid]; ok {
+			ordered = append(ordered, band)
+		}
+	}
+
+	return ordered, nil
+}
+
+// CreateOrUpdateMetersOps returns the ops to create or update the provided
+// meters, matched by Name
+func CreateOrUpdateMetersOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, meters ...*nbdb.Meter) ([]libovsdb.Operation, error) {
+	opModels := make([]OperationModel, 0, len(meters))
+	for i := range meters {
+		meter := meters[i]
+		opModel := OperationModel{
+			Model:          meter,
+			ModelPredicate: func(item *nbdb.Meter) bool { return item.Name == meter.Name },
+			OnModelUpdates: []interface{}{
+				&meter.Bands,
+				&meter.ExternalIDs,
+				&meter.Fair,
+				&meter.Unit,
+			},
+			ErrNotFound: false,
+			BulkOp:      false,
+		}
+		opModels = append(opModels, opModel)
+	}
+
+	m := NewModelClient(nbClient)
+	return m.CreateOrUpdateOps(ops, opModels...)
+}
+
+// CreateOrUpdateMeterBandsOps returns the ops to create or update the
+// provided bands, matched by UUID (bands have no other natural key)
+func CreateOrUpdateMeterBandsOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, bands ...*nbdb.MeterBand) ([]libovsdb.Operation, error) {
+	opModels := make([]OperationModel, 0, len(bands))
+	for i := range bands {
+		band := bands[i]
+		opModel := OperationModel{
+			Model:          band,
+			ModelPredicate: func(item *nbdb.MeterBand) bool { return band.UUID != "" && item.UUID == band.UUID },
+			OnModelUpdates: []interface{}{
+				&band.Action,
+				&band.BurstSize,
+				&band.ExternalIDs,
+				&band.Rate,
+			},
+			ErrNotFound: false,
+			BulkOp:      false,
+		}
+		opModels = append(opModels, opModel)
+	}
+
+	m := NewModelClient(nbClient)
+	return m.CreateOrUpdateOps(ops, opModels...)
+}
+
+// DeleteMetersOps returns the ops to delete the provided meters
+func DeleteMetersOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, meters ...*nbdb.Meter) ([]libovsdb.Operation, error) {
+	opModels := make([]OperationModel, 0, len(meters))
+	for i := range meters {
+		opModel := OperationModel{
+			Model:       meters[i],
+			ErrNotFound: false,
+			BulkOp:      false,
+		}
+		opModels = append(opModels, opModel)
+	}
+
+	m := NewModelClient(nbClient)
+	return m.DeleteOps(ops, opModels...)
+}
+
+// DeleteMeterBandsOps returns the ops to delete the provided bands
+func DeleteMeterBandsOps(nbClient libovsdbclient.Client, ops []libovsdb.Operation, bands ...*nbdb.MeterBand) ([]libovsdb.Operation, error) {
+	opModels := make([]OperationModel, 0, len(bands))
+	for i := range bands {
+		opModel := OperationModel{
+			Model:       bands[i],
+			ErrNotFound: false,
+			BulkOp:      false,
+		}
+		opModels = append(opModels, opModel)
+	}
+
+	m := NewModelClient(nbClient)
+	return m.DeleteOps(ops, opModels...)
+}
+
+// meterBandContentEquals reports whether a and b have the same
+// Action/Rate/BurstSize/ExternalIDs, ignoring UUID. band.Equals can't be
+// used for this: a freshly-built desired band always has UUID == "",
+// while a band read back from the cache always has one, so Equals (which
+// ANDs in UUID equality) would never match a desired band against an
+// existing one.
+func meterBandContentEquals(a, b *nbdb.MeterBand) bool {
+	aCopy := *a
+	bCopy := *b
+	aCopy.UUID = ""
+	bCopy.UUID = ""
+	return aCopy.Equals(&bCopy)
+}
+
+func bandUUIDs(bands []*nbdb.MeterBand) []string {
+	uuids := make([]string, 0, len(bands))
+	for _, band := range bands {
+		uuids = append(uuids, band.UUID)
+	}
+	return uuids
+}
+
+// CreateOrUpdateMeter idempotently creates or updates meter (matched by
+// Name) along with bands, atomically, in a single transaction. bands are
+// matched against meter's existing bands by content (Action, Rate,
+// BurstSize, ExternalIDs), not by position: a desired band that already
+// matches some existing band reuses that band's row untouched, a desired
+// band with no existing match is created, and any existing band matched
+// by no desired band is deleted so it doesn't leak as an orphan row.
+func CreateOrUpdateMeter(nbClient libovsdbclient.Client, meter *nbdb.Meter, bands ...*nbdb.MeterBand) error {
+	existing, err := FindMeterByName(nbClient, meter.Name)
+	if err != nil {
+		return err
+	}
+
+	var existingBands []*nbdb.MeterBand
+	if existing != nil {
+		meter.UUID = existing.UUID
+		existingBands, err = listMeterBandsByUUIDs(nbClient, existing.Bands)
+		if err != nil {
+			return err
+		}
+	}
+
+	consumed := make([]bool, len(existingBands))
+	toCreate := make([]*nbdb.MeterBand, 0, len(bands))
+	for _, band := range bands {
+		matched := false
+		for i, existingBand := range existingBands {
+			if consumed[i] {
+				continue
+			}
+			if meterBandContentEquals(band, existingBand) {
+				band.UUID = existingBand.UUID
+				consumed[i] = true
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			toCreate = append(toCreate, band)
+		}
+	}
+
+	toDelete := make([]*nbdb.MeterBand, 0, len(existingBands))
+	for i, existingBand := range existingBands {
+		if !consumed[i] {
+			toDelete = append(toDelete, existingBand)
+		}
+	}
+
+	ops, err := CreateOrUpdateMeterBandsOps(nbClient, nil, toCreate...)
+	if err != nil {
+		return err
+	}
+
+	ops, err = DeleteMeterBandsOps(nbClient, ops, toDelete...)
+	if err != nil {
+		return err
+	}
+
+	// the band ops above have assigned each new band a named-uuid, so the
+	// UUIDs are only complete now
+	meter.Bands = bandUUIDs(bands)
+	ops, err = CreateOrUpdateMetersOps(nbClient, ops, meter)
+	if err != nil {
+		return err
+	}
+
+	_, err = TransactAndCheck(nbClient, ops)
+	return err
+}
+
+// AddMeterBands looks up meterName and atomically creates bands and
+// appends them to its Bands set, skipping any band whose content already
+// matches an existing one on the meter.
+func AddMeterBands(nbClient libovsdbclient.Client, meterName string, bands ...*nbdb.MeterBand) error {
+	meter, err := FindMeterByName(nbClient, meterName)
+	if err != nil {
+		return err
+	}
+	if meter == nil {
+		return fmt.Errorf("meter %q not found", meterName)
+	}
+
+	existingBands, err := listMeterBandsByUUIDs(nbClient, meter.Bands)
+	if err != nil {
+		return err
+	}
+
+	toCreate := make([]*nbdb.MeterBand, 0, len(bands))
+	for _, band := range bands {
+		duplicate := false
+		for _, existing := range existingBands {
+			if meterBandContentEquals(band, existing) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			toCreate = append(toCreate, band)
+		}
+	}
+	if len(toCreate) == 0 {
+		return nil
+	}
+
+	ops, err := CreateOrUpdateMeterBandsOps(nbClient, nil, toCreate...)
+	if err != nil {
+		return err
+	}
+
+	meter.Bands = append(meter.Bands, bandUUIDs(toCreate)...)
+	ops, err = CreateOrUpdateMetersOps(nbClient, ops, meter)
+	if err != nil {
+		return err
+	}
+
+	_, err = TransactAndCheck(nbClient, ops)
+	return err
+}
+
+// RemoveMeterBands looks up meterName and atomically removes bands from
+// its Bands set and deletes their rows, matching each by content rather
+// than UUID since callers typically only have the desired band contents
+// on hand, not the UUID the band was assigned on creation.
+func RemoveMeterBands(nbClient libovsdbclient.Client, meterName string, bands ...*nbdb.MeterBand) error {
+	meter, err := FindMeterByName(nbClient, meterName)
+	if err != nil {
+		return err
+	}
+	if meter == nil {
+		return nil
+	}
+
+	existingBands, err := listMeterBandsByUUIDs(nbClient, meter.Bands)
+	if err != nil {
+		return err
+	}
+
+	toDelete := make([]*nbdb.MeterBand, 0, len(existingBands))
+	remaining := make([]string, 0, len(existingBands))
+	for _, existing := range existingBands {
+		remove := false
+		for _, band := range bands {
+			if meterBandContentEquals(band, existing) {
+				remove = true
+				break
+			}
+		}
+		if remove {
+			toDelete = append(toDelete, existing)
+		} else {
+			remaining = append(remaining, existing.UUID)
+		}
+	}
+	if len(toDelete) == 0 {
+		return nil
+	}
+
+	meter.Bands = remaining
+	ops, err := CreateOrUpdateMetersOps(nbClient, nil, meter)
+	if err != nil {
+		return err
+	}
+
+	ops, err = DeleteMeterBandsOps(nbClient, ops, toDelete...)
+	if err != nil {
+		return err
+	}
+
+	_, err = TransactAndCheck(nbClient, ops)
+	return err
+}
+
+// DeleteMeter deletes the named meter along with every band it references.
+// It is a no-op if no such meter exists.
+func DeleteMeter(nbClient libovsdbclient.Client, name string) error {
+	meter, err := FindMeterByName(nbClient, name)
+	if err != nil {
+		return err
+	}
+	if meter == nil {
+		return nil
+	}
+
+	bands, err := listMeterBandsByUUIDs(nbClient, meter.Bands)
+	if err != nil {
+		return err
+	}
+
+	ops, err := DeleteMetersOps(nbClient, nil, meter)
+	if err != nil {
+		return err
+	}
+
+	ops, err = DeleteMeterBandsOps(nbClient, ops, bands...)
+	if err != nil {
+		return err
+	}
+
+	_, err = TransactAndCheck(nbClient, ops)
+	return err
+}
+
+// ListOrphanMeterBands finds every Meter_Band row no longer referenced by
+// any Meter, deletes them, and returns the ones it deleted. libovsdb
+// doesn't garbage collect rows dropped from a set, so this is meant to be
+// run periodically as a sweep alongside whatever else reconciles Meters.
+func ListOrphanMeterBands(nbClient libovsdbclient.Client) ([]*nbdb.MeterBand, error) {
+	meters, err := ListMeters(nbClient)
+	if err != nil {
+		return nil, err
+	}
+	referenced := map[string]bool{}
+	for _, meter := range meters {
+		for _, uuid := range meter.Bands {
+			referenced[uuid] = true
+		}
+	}
+
+	allBands, err := ListMeterBands(nbClient)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := make([]*nbdb.MeterBand, 0)
+	for _, band := range allBands {
+		if !referenced[band.UUID] {
+			orphans = append(orphans, band)
+		}
+	}
+	if len(orphans) == 0 {
+		return nil, nil
+	}
+
+	ops, err := DeleteMeterBandsOps(nbClient, nil, orphans...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := TransactAndCheck(nbClient, ops); err != nil {
+		return nil, err
+	}
+
+	return orphans, nil
+}
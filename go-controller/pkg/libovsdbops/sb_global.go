@@ -1,13 +1,29 @@
 package libovsdbops
 
 import (
+	"context"
+
 	libovsdbclient "github.com/ovn-org/libovsdb/client"
 
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/config"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/sbdb"
 )
 
-// GetNBGlobal looks up the SB Global entry from the cache
+// GetSBGlobal looks up the SB Global entry from the cache, bounding how
+// long it waits for the lookup by config.OVSDB.TxnTimeout. Callers that need a
+// different deadline (or none at all) should use GetSBGlobalWithContext.
 func GetSBGlobal(sbClient libovsdbclient.Client, sbGlobal *sbdb.SBGlobal) (*sbdb.SBGlobal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.OVSDB.TxnTimeout)
+	defer cancel()
+	return GetSBGlobalWithContext(ctx, sbClient, sbGlobal)
+}
+
+// GetSBGlobalWithContext is like GetSBGlobal but lets the caller supply its
+// own deadline instead of the configured default. ModelClient doesn't
+// expose its cache lookup and Transact call as separate steps, so ctx
+// bounds the whole CreateOrUpdate call below, cache lookup included, not
+// just the Transact portion of it.
+func GetSBGlobalWithContext(ctx context.Context, sbClient libovsdbclient.Client, sbGlobal *sbdb.SBGlobal) (*sbdb.SBGlobal, error) {
 	found := []*sbdb.SBGlobal{}
 	opModel := OperationModel{
 		Model:          sbGlobal,
@@ -19,7 +35,10 @@ func GetSBGlobal(sbClient libovsdbclient.Client, sbGlobal *sbdb.SBGlobal) (*sbdb
 	}
 
 	m := NewModelClient(sbClient)
-	_, err := m.CreateOrUpdate(opModel)
+	err := withContext(ctx, func() error {
+		_, err := m.CreateOrUpdate(opModel)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,114 @@
+package libovsdbops
+
+import (
+	libovsdbclient "github.com/ovn-org/libovsdb/client"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/vswitchd"
+)
+
+// GetOpenvSwitch looks up the (singleton) Open_vSwitch row from the cache
+func GetOpenvSwitch(vsClient libovsdbclient.Client) (*vswitchd.OpenvSwitch, error) {
+	found := []*vswitchd.OpenvSwitch{}
+	opModel := OperationModel{
+		Model:          &vswitchd.OpenvSwitch{},
+		ModelPredicate: func(item *vswitchd.OpenvSwitch) bool { return true },
+		ExistingResult: &found,
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := NewModelClient(vsClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+
+	return found[0], nil
+}
+
+// GetBridgeByName looks up the named Bridge row from the cache
+func GetBridgeByName(vsClient libovsdbclient.Client, name string) (*vswitchd.Bridge, error) {
+	found := []*vswitchd.Bridge{}
+	opModel := OperationModel{
+		Model:          &vswitchd.Bridge{Name: name},
+		ModelPredicate: func(item *vswitchd.Bridge) bool { return item.Name == name },
+		ExistingResult: &found,
+		ErrNotFound:    true,
+		BulkOp:         false,
+	}
+
+	m := NewModelClient(vsClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+
+	return found[0], nil
+}
+
+// CreateOrUpdatePort creates or updates port, matched by name. Callers that
+// need the port attached to a bridge are responsible for appending its UUID
+// to the bridge's Ports once this returns.
+func CreateOrUpdatePort(vsClient libovsdbclient.Client, port *vswitchd.Port) error {
+	opModel := OperationModel{
+		Model:          port,
+		ModelPredicate: func(item *vswitchd.Port) bool { return item.Name == port.Name },
+		OnModelUpdates: []interface{}{
+			&port.ExternalIDs,
+			&port.Interfaces,
+			&port.QOS,
+			&port.Tag,
+		},
+		ErrNotFound: false,
+		BulkOp:      false,
+	}
+
+	m := NewModelClient(vsClient)
+	_, err := m.CreateOrUpdate(opModel)
+	return err
+}
+
+// CreateOrUpdateInterface creates or updates iface, matched by name
+func CreateOrUpdateInterface(vsClient libovsdbclient.Client, iface *vswitchd.Interface) error {
+	opModel := OperationModel{
+		Model:          iface,
+		ModelPredicate: func(item *vswitchd.Interface) bool { return item.Name == iface.Name },
+		OnModelUpdates: []interface{}{
+			&iface.ExternalIDs,
+			&iface.MTURequest,
+			&iface.Options,
+			&iface.Type,
+		},
+		ErrNotFound: false,
+		BulkOp:      false,
+	}
+
+	m := NewModelClient(vsClient)
+	_, err := m.CreateOrUpdate(opModel)
+	return err
+}
+
+// FindQoSByExternalIDs looks up every QoS row whose ExternalIDs is a
+// superset of externalIDs
+func FindQoSByExternalIDs(vsClient libovsdbclient.Client, externalIDs map[string]string) ([]*vswitchd.QoS, error) {
+	found := []*vswitchd.QoS{}
+	opModel := OperationModel{
+		Model: &vswitchd.QoS{},
+		ModelPredicate: func(item *vswitchd.QoS) bool {
+			for k, v := range externalIDs {
+				if w, ok := item.ExternalIDs[k]; !ok || w != v {
+					return false
+				}
+			}
+			return true
+		},
+		ExistingResult: &found,
+		ErrNotFound:    false,
+		BulkOp:         true,
+	}
+
+	m := NewModelClient(vsClient)
+	if _, err := m.CreateOrUpdate(opModel); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
@@ -2,13 +2,18 @@ package networkAttachDefController
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -16,13 +21,27 @@ import (
 	"k8s.io/klog/v2"
 
 	nettypes "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	nadclientset "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/clientset/versioned"
 	nadinformers "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/informers/externalversions/k8s.cni.cncf.io/v1"
 	nadlisters "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/listers/k8s.cni.cncf.io/v1"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/controller"
+	ratypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/routeadvertisements/v1/apis/apis/routeadvertisements/v1"
 	rainformers "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/routeadvertisements/v1/apis/informers/externalversions/routeadvertisements/v1"
+	ralisters "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/routeadvertisements/v1/apis/listers/routeadvertisements/v1"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/types"
 	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
 )
 
+// defaultVRFName is the VRF that carries the default cluster network on every
+// node, regardless of whether any RouteAdvertisements select it.
+const defaultVRFName = "default"
+
+// defaultNetworkNamespace is the well-known namespace in which a NAD
+// defining the default cluster network may live. NADs outside this
+// namespace are never considered default-network NADs, even if their parsed
+// NetInfo happens to report types.DefaultNetworkName.
+const defaultNetworkNamespace = "ovn-kubernetes"
+
 var ErrNetworkControllerTopologyNotManaged = errors.New("no cluster network controller to manage topology")
 
 type ReconcilableNetworkController interface {
@@ -39,8 +58,10 @@ type NetworkController interface {
 	BaseNetworkController
 	util.NetInfo
 	// Cleanup cleans up the NetworkController-owned resources, it could be called to clean up network controllers that are deleted when
-	// ovn-k8s is down; so it's receiver could be a dummy network controller, it just needs to know its network name.
-	Cleanup() error
+	// ovn-k8s is down; so it's receiver could be a dummy network controller, it just needs to know its network name. It returns the
+	// number of network resources (logical switch ports, ACLs, ...) it still owns after the attempt, so a caller driving a drain phase
+	// knows when it is finally safe to tear down the network entirely.
+	Cleanup() (remaining int, err error)
 }
 
 // NetworkControllerManager manages all network controllers
@@ -54,6 +75,19 @@ type watchFactory interface {
 	NADInformer() nadinformers.NetworkAttachmentDefinitionInformer
 	RouteAdvertisementsInformer() rainformers.RouteAdvertisementsInformer
 	NodeCoreInformer() coreinformers.NodeInformer
+	NADClient() nadclientset.Interface
+}
+
+// nadFinalizer protects a NAD from being garbage collected before its
+// network has finished draining: its referenced network controller has
+// released every logical-switch-port/ACL it owns on behalf of pods that
+// used to be attached through it.
+const nadFinalizer = "k8s.ovn.org/network-attach-def-protection"
+
+// nodeLister is the subset of the node lister the VRF resolver needs to
+// evaluate a RouteAdvertisements NodeSelector
+type nodeLister interface {
+	List(selector labels.Selector) (ret []*corev1.Node, err error)
 }
 
 // NADController handles namespaced scoped NAD events and
@@ -65,15 +99,28 @@ type watchFactory interface {
 type NADController struct {
 	name               string
 	netAttachDefLister nadlisters.NetworkAttachmentDefinitionLister
+	nadClient          nadclientset.Interface
+	routeAdvLister     ralisters.RouteAdvertisementsLister
+	nodeLister         nodeLister
 	controller         controller.Controller
+	routeAdvController controller.Controller
 
 	// networkManager is used to manage the network controllers
 	networkManager networkManager
 
+	// ncm gives access to the default network controller, which default
+	// network NADs are reconciled against directly instead of going through
+	// networkManager
+	ncm NetworkControllerManager
+
 	networks map[string]util.NetInfo
 
 	// nads to network mapping
 	nads map[string]string
+
+	// defaultNetworkNADs holds the default network NADs currently known, so
+	// that their annotations can be merged on every add/update/delete
+	defaultNetworkNADs map[string]*nettypes.NetworkAttachmentDefinition
 }
 
 // NewClusterNADController builds a NAD controller for cluster manager
@@ -133,9 +180,14 @@ func newNADController(
 	c := &NADController{
 		name:               fmt.Sprintf("[%s NAD controller]", name),
 		netAttachDefLister: wf.NADInformer().Lister(),
+		nadClient:          wf.NADClient(),
+		routeAdvLister:     wf.RouteAdvertisementsInformer().Lister(),
+		nodeLister:         wf.NodeCoreInformer().Lister(),
 		networkManager:     newNetworkManager(name, zone, node, ncm, wf),
+		ncm:                ncm,
 		networks:           map[string]util.NetInfo{},
 		nads:               map[string]string{},
+		defaultNetworkNADs: map[string]*nettypes.NetworkAttachmentDefinition{},
 	}
 	config := &controller.ControllerConfig[nettypes.NetworkAttachmentDefinition]{
 		RateLimiter:    workqueue.DefaultControllerRateLimiter(),
@@ -151,6 +203,21 @@ func newNADController(
 		config,
 	)
 
+	raConfig := &controller.ControllerConfig[ratypes.RouteAdvertisements]{
+		RateLimiter: workqueue.DefaultControllerRateLimiter(),
+		Informer:    wf.RouteAdvertisementsInformer().Informer(),
+		Lister:      c.routeAdvLister.List,
+		Reconcile:   c.syncRouteAdvertisements,
+		// a RouteAdvertisements change always requires re-evaluating the
+		// NADs it may affect, there is no cheap way to filter this down
+		ObjNeedsUpdate: func(oldRA, newRA *ratypes.RouteAdvertisements) bool { return true },
+		Threadiness:    1,
+	}
+	c.routeAdvController = controller.NewController(
+		fmt.Sprintf("[%s route advertisements controller]", name),
+		raConfig,
+	)
+
 	return c, nil
 }
 
@@ -166,6 +233,11 @@ func (c *NADController) Start() error {
 		return err
 	}
 
+	err = controller.Start(c.routeAdvController)
+	if err != nil {
+		return err
+	}
+
 	err = c.networkManager.Start()
 	if err != nil {
 		return err
@@ -178,6 +250,7 @@ func (c *NADController) Start() error {
 func (c *NADController) Stop() {
 	klog.Infof("%s: shutting down", c.name)
 	controller.Stop(c.controller)
+	controller.Stop(c.routeAdvController)
 	c.networkManager.Stop()
 }
 
@@ -241,6 +314,36 @@ func (c *NADController) syncNAD(key string, nad *nettypes.NetworkAttachmentDefin
 		nadNetworkName = nadNetwork.GetNetworkName()
 	}
 
+	// default network NADs are not tracked as regular networks; they are
+	// reconciled directly against the default network controller instead
+	// of going through networkManager.EnsureNetwork
+	_, wasDefaultNetworkNAD := c.defaultNetworkNADs[key]
+	isDefaultNetworkNAD := nad != nil && isDefaultNetworkNamespacedName(key, nadNetworkName)
+	if isDefaultNetworkNAD {
+		return c.syncDefaultNetworkNAD(key, nad)
+	}
+	if wasDefaultNetworkNAD {
+		// This NAD used to parse to the default network but no longer
+		// does (e.g. its CNI config was edited in place to point at a
+		// different network). Drop it from the default network's NAD
+		// set and reconcile the default network controller without it,
+		// then fall through to the normal secondary-network handling
+		// below instead of leaving it stuck here forever.
+		if err := c.syncDefaultNetworkNAD(key, nil); err != nil {
+			return err
+		}
+	}
+
+	if nad != nil && nad.GetDeletionTimestamp().IsZero() {
+		if err := c.ensureFinalizer(nad); err != nil {
+			return fmt.Errorf("%s: failed ensuring finalizer on NAD %s: %w", c.name, key, err)
+		}
+	}
+
+	if nad != nil && !nad.GetDeletionTimestamp().IsZero() {
+		return c.syncTerminatingNAD(key, nad, nadNetworkName)
+	}
+
 	// As multiple NADs may define networks with the same name, these networks
 	// should also have the same config to be considered compatible. If an
 	// incompatible network change happens on NAD update, we can:
@@ -304,18 +407,293 @@ func (c *NADController) syncNAD(key string, nad *nettypes.NetworkAttachmentDefin
 	// in case route advertisements changed
 	ensureNetwork.AddNADs(key)
 	c.nads[key] = ensureNetwork.GetNetworkName()
+
+	vrfs, err := c.computeVRFs(ensureNetwork)
+	if err != nil {
+		return fmt.Errorf("%s: failed computing VRFs for network %s: %w", c.name, ensureNetwork.GetNetworkName(), err)
+	}
+	ensureNetwork.SetVRFs(vrfs)
+
 	c.networkManager.EnsureNetwork(ensureNetwork)
 	return err
 }
 
+// syncTerminatingNAD begins or continues the drain phase for a NAD that has
+// a DeletionTimestamp. Admission of new pods against its network is stopped
+// immediately by dropping the NAD's reference, but the finalizer (and
+// therefore the NAD object itself) is kept around until
+// NetworkController.Cleanup() reports no remaining resources, so in-flight
+// pod teardown can't lose the race with the NAD disappearing.
+func (c *NADController) syncTerminatingNAD(key string, nad *nettypes.NetworkAttachmentDefinition, nadNetworkName string) error {
+	network := c.networks[nadNetworkName]
+	if network == nil {
+		// never admitted, or already fully drained across a restart
+		delete(c.nads, key)
+		return c.removeFinalizer(nad)
+	}
+
+	network.DeleteNADs(key)
+	delete(c.nads, key)
+
+	if len(network.GetNADs()) > 0 {
+		// other NADs still reference this network, nothing to drain
+		c.networkManager.EnsureNetwork(network)
+		return c.removeFinalizer(nad)
+	}
+
+	networkController, ok := c.networkManager.GetNetworkController(nadNetworkName)
+	if !ok {
+		// network controller was never created (e.g. it failed to start),
+		// there is nothing to drain
+		c.networkManager.DeleteNetwork(nadNetworkName)
+		delete(c.networks, nadNetworkName)
+		return c.removeFinalizer(nad)
+	}
+
+	remaining, err := networkController.Cleanup()
+	if err != nil {
+		return fmt.Errorf("%s: failed draining network %s: %w", c.name, nadNetworkName, err)
+	}
+	if remaining > 0 {
+		// keep the network ensured (but no longer admitting new pods through
+		// this NAD) and requeue: there is no event that would otherwise wake
+		// us up once the last pod using this network finishes deleting
+		c.networkManager.EnsureNetwork(network)
+		return fmt.Errorf("%s: network %s still draining, %d resources remaining", c.name, nadNetworkName, remaining)
+	}
+
+	c.networkManager.DeleteNetwork(nadNetworkName)
+	delete(c.networks, nadNetworkName)
+	return c.removeFinalizer(nad)
+}
+
+// ensureFinalizer patches nadFinalizer onto nad if it isn't already present.
+func (c *NADController) ensureFinalizer(nad *nettypes.NetworkAttachmentDefinition) error {
+	if sets.New(nad.Finalizers...).Has(nadFinalizer) {
+		return nil
+	}
+	return c.patchFinalizers(nad, append(append([]string{}, nad.Finalizers...), nadFinalizer))
+}
+
+// removeFinalizer patches nadFinalizer off of nad if it is present.
+func (c *NADController) removeFinalizer(nad *nettypes.NetworkAttachmentDefinition) error {
+	finalizers := sets.New(nad.Finalizers...)
+	if !finalizers.Has(nadFinalizer) {
+		return nil
+	}
+	finalizers.Delete(nadFinalizer)
+	return c.patchFinalizers(nad, sets.List(finalizers))
+}
+
+// patchFinalizers issues a merge patch setting nad's finalizers list.
+func (c *NADController) patchFinalizers(nad *nettypes.NetworkAttachmentDefinition, finalizers []string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": nad.ResourceVersion,
+			"finalizers":      finalizers,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.nadClient.K8sCniCncfIoV1().NetworkAttachmentDefinitions(nad.Namespace).Patch(
+		context.TODO(), nad.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// networkSelectorLabel is the well-known label carried by the synthetic
+// label set used to match a RouteAdvertisements NetworkSelector against a
+// network name, mirroring how NetworkSelector is evaluated elsewhere
+// against NAD/network metadata.
+const networkSelectorLabel = "k8s.ovn.org/network-name"
+
+// computeVRFs resolves all RouteAdvertisements selecting netInfo's network
+// into a per-node VRF mapping. Every known node defaults to the default VRF;
+// nodes matched by a RouteAdvertisements' NodeSelector are moved into that
+// RA's TargetVRF when it names one, and stay in the default VRF when it
+// does not. No per-node user-defined VRF is computed.
+func (c *NADController) computeVRFs(netInfo util.NetInfo) (map[string]string, error) {
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed listing nodes: %w", err)
+	}
+
+	vrfs := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		vrfs[node.Name] = defaultVRFName
+	}
+
+	ras, err := c.routeAdvLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed listing route advertisements: %w", err)
+	}
+
+	networkLabels := labels.Set{networkSelectorLabel: netInfo.GetNetworkName()}
+	for _, ra := range ras {
+		networkSelector, err := metav1.LabelSelectorAsSelector(&ra.Spec.NetworkSelector)
+		if err != nil {
+			klog.Errorf("%s: failed parsing NetworkSelector of RouteAdvertisements %s: %v", c.name, ra.Name, err)
+			continue
+		}
+		if !networkSelector.Matches(networkLabels) {
+			continue
+		}
+
+		nodeSelector, err := metav1.LabelSelectorAsSelector(&ra.Spec.NodeSelector)
+		if err != nil {
+			klog.Errorf("%s: failed parsing NodeSelector of RouteAdvertisements %s: %v", c.name, ra.Name, err)
+			continue
+		}
+
+		vrf := ra.Spec.TargetVRF
+		if vrf == "" {
+			vrf = defaultVRFName
+		}
+
+		for _, node := range nodes {
+			if nodeSelector.Matches(labels.Set(node.Labels)) {
+				vrfs[node.Name] = vrf
+			}
+		}
+	}
+
+	return vrfs, nil
+}
+
+// syncRouteAdvertisements reconciles a single RouteAdvertisements change by
+// re-ensuring every network we currently track, and reconciling the default
+// network controller, with each network's VRF mapping recomputed from
+// scratch by computeVRFs, so this is sufficient regardless of whether the
+// RouteAdvertisements was added, updated or deleted.
+func (c *NADController) syncRouteAdvertisements(key string) error {
+	klog.V(5).Infof("%s: sync RouteAdvertisements %s", c.name, key)
+	for _, netInfo := range c.networks {
+		vrfs, err := c.computeVRFs(netInfo)
+		if err != nil {
+			return fmt.Errorf("%s: failed computing VRFs for network %s: %w", c.name, netInfo.GetNetworkName(), err)
+		}
+		netInfo.SetVRFs(vrfs)
+		c.networkManager.EnsureNetwork(netInfo)
+	}
+
+	// the default network is tracked apart from c.networks
+	merged, err := mergeDefaultNetworkNADs(c.defaultNetworkNADs)
+	if err != nil {
+		return fmt.Errorf("%s: failed merging default network NADs: %w", c.name, err)
+	}
+	vrfs, err := c.computeVRFs(merged)
+	if err != nil {
+		return fmt.Errorf("%s: failed computing VRFs for default network: %w", c.name, err)
+	}
+	merged.SetVRFs(vrfs)
+	return c.ncm.GetDefaultNetworkController().Reconcile(merged)
+}
+
+// isDefaultNetworkNamespacedName reports whether the given NAD key/network
+// name pair refers to a default-network NAD: one living in
+// defaultNetworkNamespace whose parsed network name is the default network.
+func isDefaultNetworkNamespacedName(key, networkName string) bool {
+	namespace, _, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return false
+	}
+	return namespace == defaultNetworkNamespace && networkName == types.DefaultNetworkName
+}
+
+// syncDefaultNetworkNAD handles add/update/delete of a single default
+// network NAD. Unlike secondary networks, the default network controller is
+// never created or torn down here: it always exists, and is simply
+// reconciled with the merged configuration of whatever default-network NADs
+// currently exist (or with a baseline default NetInfo when none do).
+func (c *NADController) syncDefaultNetworkNAD(key string, nad *nettypes.NetworkAttachmentDefinition) error {
+	if nad == nil || !nad.GetDeletionTimestamp().IsZero() {
+		delete(c.defaultNetworkNADs, key)
+	} else {
+		c.defaultNetworkNADs[key] = nad
+	}
+
+	merged, err := mergeDefaultNetworkNADs(c.defaultNetworkNADs)
+	if err != nil {
+		return fmt.Errorf("%s: failed merging default network NADs: %w", c.name, err)
+	}
+
+	vrfs, err := c.computeVRFs(merged)
+	if err != nil {
+		return fmt.Errorf("%s: failed computing VRFs for default network: %w", c.name, err)
+	}
+	merged.SetVRFs(vrfs)
+
+	return c.ncm.GetDefaultNetworkController().Reconcile(merged)
+}
+
+// mergeDefaultNetworkNADs merges zero, one or many default-network NADs into
+// a single NetInfo to reconcile the default network controller with. With no
+// NADs, a baseline default NetInfo is returned. With one or more, their
+// route advertisements annotations are unioned onto the parsed NetInfo of
+// any one of them (their CNI config is otherwise expected to be identical,
+// since they all define the default network).
+func mergeDefaultNetworkNADs(nads map[string]*nettypes.NetworkAttachmentDefinition) (util.NetInfo, error) {
+	if len(nads) == 0 {
+		return util.NewDefaultNetInfo(), nil
+	}
+
+	var merged util.NetInfo
+	for key, nad := range nads {
+		netInfo, err := util.ParseNADInfo(nad)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing default network NAD %s: %w", key, err)
+		}
+		if merged == nil {
+			merged = netInfo
+			continue
+		}
+		if !merged.Equals(netInfo) {
+			return nil, fmt.Errorf("default network NAD %s CNI config does not match other default network NADs", key)
+		}
+	}
+
+	merged.SetRouteAdvertisements(mergeRouteAdvertisements(nads))
+	for key := range nads {
+		merged.AddNADs(key)
+	}
+
+	return merged, nil
+}
+
+// mergeRouteAdvertisements unions the comma separated OvnRouteAdvertisements
+// annotation values of the given NADs into a single, deduplicated,
+// deterministically ordered comma separated list.
+func mergeRouteAdvertisements(nads map[string]*nettypes.NetworkAttachmentDefinition) string {
+	merged := sets.New[string]()
+	for _, nad := range nads {
+		for _, ra := range strings.Split(nad.Annotations[util.OvnRouteAdvertisements], ",") {
+			if ra = strings.TrimSpace(ra); ra != "" {
+				merged.Insert(ra)
+			}
+		}
+	}
+	return strings.Join(sets.List(merged), ",")
+}
+
 func nadNeedsUpdate(oldNAD, newNAD *nettypes.NetworkAttachmentDefinition) bool {
 	if oldNAD == nil || newNAD == nil {
 		return true
 	}
 
-	// don't process resync or objects that are marked for deletion
-	if oldNAD.ResourceVersion == newNAD.ResourceVersion ||
-		!newNAD.GetDeletionTimestamp().IsZero() {
+	// don't process resyncs
+	if oldNAD.ResourceVersion == newNAD.ResourceVersion {
+		return false
+	}
+
+	// always process the terminal transition into deletion so the drain
+	// phase can begin, even when nothing else about the object changed
+	if oldNAD.GetDeletionTimestamp().IsZero() && !newNAD.GetDeletionTimestamp().IsZero() {
+		return true
+	}
+
+	// once terminating, further updates (e.g. our own finalizer removal) are
+	// not interesting: the drain phase drives itself to completion
+	if !newNAD.GetDeletionTimestamp().IsZero() {
 		return false
 	}
 
@@ -0,0 +1,162 @@
+package networkAttachDefController
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	nettypes "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	ratypes "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/routeadvertisements/v1/apis/apis/routeadvertisements/v1"
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/util"
+)
+
+// fakeNodeLister and fakeRouteAdvLister are empty stand-ins so
+// computeVRFs has something to call without requiring real informers.
+type fakeNodeLister struct{}
+
+func (fakeNodeLister) List(selector labels.Selector) ([]*corev1.Node, error) { return nil, nil }
+
+type fakeRouteAdvLister struct{}
+
+func (fakeRouteAdvLister) List(selector labels.Selector) ([]*ratypes.RouteAdvertisements, error) {
+	return nil, nil
+}
+
+func (fakeRouteAdvLister) Get(name string) (*ratypes.RouteAdvertisements, error) {
+	return nil, nil
+}
+
+// fakeDefaultNetworkController records every NetInfo it is asked to
+// reconcile, in order, so tests can assert on add/update/delete ordering.
+type fakeDefaultNetworkController struct {
+	reconciled []util.ReconcilableNetInfo
+}
+
+func (f *fakeDefaultNetworkController) Reconcile(netInfo util.ReconcilableNetInfo) error {
+	f.reconciled = append(f.reconciled, netInfo)
+	return nil
+}
+
+type fakeNetworkControllerManager struct {
+	defaultNetworkController *fakeDefaultNetworkController
+}
+
+func (f *fakeNetworkControllerManager) NewNetworkController(netInfo util.NetInfo) (NetworkController, error) {
+	return nil, nil
+}
+
+func (f *fakeNetworkControllerManager) CleanupDeletedNetworks(validNetworks ...util.BasicNetInfo) error {
+	return nil
+}
+
+func (f *fakeNetworkControllerManager) GetDefaultNetworkController() ReconcilableNetworkController {
+	return f.defaultNetworkController
+}
+
+const defaultNetworkNADConfig = `{"cniVersion":"0.4.0","name":"` + "default" + `","type":"ovn-k8s-cni-overlay"}`
+
+func defaultNetworkNAD(namespace, name, routeAdvertisements string) *nettypes.NetworkAttachmentDefinition {
+	return &nettypes.NetworkAttachmentDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Annotations: map[string]string{util.OvnRouteAdvertisements: routeAdvertisements},
+		},
+		Spec: nettypes.NetworkAttachmentDefinitionSpec{
+			Config: defaultNetworkNADConfig,
+		},
+	}
+}
+
+func TestMergeDefaultNetworkNADs(t *testing.T) {
+	tests := []struct {
+		name string
+		nads map[string]*nettypes.NetworkAttachmentDefinition
+		want string
+	}{
+		{
+			name: "no NADs falls back to baseline default NetInfo",
+			nads: map[string]*nettypes.NetworkAttachmentDefinition{},
+			want: "",
+		},
+		{
+			name: "single NAD",
+			nads: map[string]*nettypes.NetworkAttachmentDefinition{
+				"ovn-kubernetes/default": defaultNetworkNAD("ovn-kubernetes", "default", "ra-a"),
+			},
+			want: "ra-a",
+		},
+		{
+			name: "multiple NADs union their route advertisements",
+			nads: map[string]*nettypes.NetworkAttachmentDefinition{
+				"ovn-kubernetes/default":  defaultNetworkNAD("ovn-kubernetes", "default", "ra-a,ra-b"),
+				"ovn-kubernetes/default2": defaultNetworkNAD("ovn-kubernetes", "default2", "ra-b,ra-c"),
+			},
+			want: "ra-a,ra-b,ra-c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeDefaultNetworkNADs(tt.nads)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := merged.GetRouteAdvertisements(); got != tt.want {
+				t.Errorf("got route advertisements %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncDefaultNetworkNADOrdering(t *testing.T) {
+	ncm := &fakeNetworkControllerManager{defaultNetworkController: &fakeDefaultNetworkController{}}
+	c := &NADController{
+		name:               "[test NAD controller]",
+		ncm:                ncm,
+		nodeLister:         fakeNodeLister{},
+		routeAdvLister:     fakeRouteAdvLister{},
+		networks:           map[string]util.NetInfo{},
+		nads:               map[string]string{},
+		defaultNetworkNADs: map[string]*nettypes.NetworkAttachmentDefinition{},
+	}
+
+	// add the first default network NAD
+	if err := c.syncDefaultNetworkNAD("ovn-kubernetes/default", defaultNetworkNAD("ovn-kubernetes", "default", "ra-a")); err != nil {
+		t.Fatalf("unexpected error on add: %v", err)
+	}
+	// add a second, coexisting default network NAD
+	if err := c.syncDefaultNetworkNAD("ovn-kubernetes/default2", defaultNetworkNAD("ovn-kubernetes", "default2", "ra-b")); err != nil {
+		t.Fatalf("unexpected error on add: %v", err)
+	}
+	// delete the first, the second should remain and still be reflected
+	if err := c.syncDefaultNetworkNAD("ovn-kubernetes/default", nil); err != nil {
+		t.Fatalf("unexpected error on delete: %v", err)
+	}
+
+	reconciled := ncm.defaultNetworkController.reconciled
+	if len(reconciled) != 3 {
+		t.Fatalf("expected 3 reconcile calls, got %d", len(reconciled))
+	}
+	if got := reconciled[2].GetRouteAdvertisements(); got != "ra-b" {
+		t.Errorf("after deleting the first NAD, expected only ra-b to remain, got %q", got)
+	}
+
+	// delete the last remaining default network NAD: must reset to baseline,
+	// not leave the default controller unreconciled
+	if err := c.syncDefaultNetworkNAD("ovn-kubernetes/default2", nil); err != nil {
+		t.Fatalf("unexpected error on last delete: %v", err)
+	}
+	reconciled = ncm.defaultNetworkController.reconciled
+	if len(reconciled) != 4 {
+		t.Fatalf("expected 4 reconcile calls, got %d", len(reconciled))
+	}
+	if got := reconciled[3].GetRouteAdvertisements(); got != "" {
+		t.Errorf("after deleting the last NAD, expected baseline (no route advertisements), got %q", got)
+	}
+	if len(c.defaultNetworkNADs) != 0 {
+		t.Errorf("expected no default network NADs to remain tracked, got %d", len(c.defaultNetworkNADs))
+	}
+}
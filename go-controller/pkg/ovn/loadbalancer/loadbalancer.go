@@ -3,6 +3,7 @@ package loadbalancer
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -58,6 +59,14 @@ func EnsureLBs(nbClient libovsdbclient.Client, externalIDs map[string]string, LB
 	if err != nil {
 		return err
 	}
+	lbToHealthCheck, err := listHealthChecks(nbClient, existing)
+	if err != nil {
+		return err
+	}
+	lbToMeter, meterToBand, err := listMeters(nbClient, existing)
+	if err != nil {
+		return err
+	}
 
 	for _, lb := range existing {
 		// collision - somehow things didn't come together how we expected
@@ -82,12 +91,31 @@ func EnsureLBs(nbClient libovsdbclient.Client, externalIDs map[string]string, LB
 	addLBsToGroups := map[string][]*nbdb.LoadBalancer{}
 	removeLBsFromGroups := map[string][]*nbdb.LoadBalancer{}
 
+	wantHCs := make([]*nbdb.LoadBalancerHealthCheck, 0, len(LBs))
+	deleteHCs := []*nbdb.LoadBalancerHealthCheck{}
+	switchIPPortMappings := map[string]map[string]string{}
+	// lbHCs pairs each LB needing a health check with its (not yet
+	// transacted) health check model, so its UUID can be wired onto the LB
+	// once the health check ops have assigned it a named-uuid
+	lbHCs := map[*nbdb.LoadBalancer]*nbdb.LoadBalancerHealthCheck{}
+
+	wantMeters := make([]*nbdb.Meter, 0, len(LBs))
+	deleteMeters := []*nbdb.Meter{}
+	wantBands := make([]*nbdb.MeterBand, 0, len(LBs))
+	deleteBands := []*nbdb.MeterBand{}
+	// meterBands pairs each Meter needing a band with its (not yet
+	// transacted) band model, so the band's UUID can be wired onto
+	// Meter.Bands once the band ops have assigned it a named-uuid
+	meterBands := map[*nbdb.Meter]*nbdb.MeterBand{}
+
 	for _, lb := range LBs {
 		blb := buildLB(&lb)
 		existingLB, exists := existingByName[lb.Name]
 		existingRouters := sets.String{}
 		existingSwitches := sets.String{}
 		existingGroups := sets.String{}
+		var existingHC *nbdb.LoadBalancerHealthCheck
+		var existingMeter *nbdb.Meter
 		if exists {
 			blb.UUID = existingLB.UUID
 			existinglbs = append(existinglbs, blb)
@@ -95,6 +123,8 @@ func EnsureLBs(nbClient libovsdbclient.Client, externalIDs map[string]string, LB
 			existingRouters = lbToRouterNames[existingLB.UUID]
 			existingSwitches = lbToSwitchNames[existingLB.UUID]
 			existingGroups = lbToGroupNames[existingLB.UUID]
+			existingHC = lbToHealthCheck[existingLB.UUID]
+			existingMeter = lbToMeter[existingLB.UUID]
 		} else {
 			newlbs = append(newlbs, blb)
 		}
@@ -107,9 +137,90 @@ func EnsureLBs(nbClient libovsdbclient.Client, externalIDs map[string]string, LB
 		mapLBDifferenceByKey(removesLBsFromRouter, existingRouters, wantRouters, blb)
 		mapLBDifferenceByKey(addLBsToGroups, wantGroups, existingGroups, blb)
 		mapLBDifferenceByKey(removeLBsFromGroups, existingGroups, wantGroups, blb)
+
+		if lb.Opts.HealthCheck != nil {
+			hc := buildHealthCheck(&lb)
+			if existingHC != nil {
+				hc.UUID = existingHC.UUID
+			}
+			wantHCs = append(wantHCs, hc)
+			lbHCs[blb] = hc
+			for sw, mapping := range lb.Opts.HealthCheck.SwitchPortMappings {
+				if switchIPPortMappings[sw] == nil {
+					switchIPPortMappings[sw] = map[string]string{}
+				}
+				for port, val := range mapping {
+					switchIPPortMappings[sw][port] = val
+				}
+			}
+		} else if existingHC != nil {
+			blb.HealthCheck = nil
+			deleteHCs = append(deleteHCs, existingHC)
+		}
+
+		if maxConn, maxConnPerSec := effectiveLimits(&lb); maxConn > 0 || maxConnPerSec > 0 {
+			meter, band := buildMeter(&lb, maxConn, maxConnPerSec)
+			if existingMeter != nil {
+				meter.UUID = existingMeter.UUID
+				if existingBand, ok := meterToBand[existingMeter.UUID]; ok {
+					band.UUID = existingBand.UUID
+				}
+			}
+			blb.Options["rate_limit_meter"] = meter.Name
+			wantMeters = append(wantMeters, meter)
+			wantBands = append(wantBands, band)
+			meterBands[meter] = band
+		} else if existingMeter != nil {
+			delete(blb.Options, "rate_limit_meter")
+			deleteMeters = append(deleteMeters, existingMeter)
+			if existingBand, ok := meterToBand[existingMeter.UUID]; ok {
+				deleteBands = append(deleteBands, existingBand)
+			}
+		}
 	}
 
-	ops, err := libovsdbops.CreateOrUpdateLoadBalancersOps(nbClient, nil, existinglbs...)
+	// garbage collect health checks and rate-limit meters whose owning LB
+	// is being deleted outright
+	for uuid := range toDelete {
+		if hc, ok := lbToHealthCheck[uuid]; ok {
+			deleteHCs = append(deleteHCs, hc)
+		}
+		if m, ok := lbToMeter[uuid]; ok {
+			deleteMeters = append(deleteMeters, m)
+			if b, ok := meterToBand[m.UUID]; ok {
+				deleteBands = append(deleteBands, b)
+			}
+		}
+	}
+
+	ops, err := libovsdbops.CreateOrUpdateLoadBalancerHealthChecksOps(nbClient, nil, wantHCs...)
+	if err != nil {
+		return err
+	}
+
+	// the health check ops above have assigned each new health check a
+	// named-uuid; now that it's known, wire it onto its owning LB
+	for blb, hc := range lbHCs {
+		blb.HealthCheck = []string{hc.UUID}
+	}
+
+	ops, err = libovsdbops.CreateOrUpdateMeterBandsOps(nbClient, ops, wantBands...)
+	if err != nil {
+		return err
+	}
+
+	// the band ops above have assigned each new band a named-uuid; now
+	// that it's known, wire it onto its owning meter
+	for meter, band := range meterBands {
+		meter.Bands = []string{band.UUID}
+	}
+
+	ops, err = libovsdbops.CreateOrUpdateMetersOps(nbClient, ops, wantMeters...)
+	if err != nil {
+		return err
+	}
+
+	ops, err = libovsdbops.CreateOrUpdateLoadBalancersOps(nbClient, ops, existinglbs...)
 	if err != nil {
 		return err
 	}
@@ -200,6 +311,47 @@ func EnsureLBs(nbClient libovsdbclient.Client, externalIDs map[string]string, LB
 		return err
 	}
 
+	ops, err = libovsdbops.DeleteLoadBalancerHealthChecksOps(nbClient, ops, deleteHCs...)
+	if err != nil {
+		return err
+	}
+
+	ops, err = libovsdbops.DeleteMetersOps(nbClient, ops, deleteMeters...)
+	if err != nil {
+		return err
+	}
+
+	ops, err = libovsdbops.DeleteMeterBandsOps(nbClient, ops, deleteBands...)
+	if err != nil {
+		return err
+	}
+
+	for sw, mappings := range switchIPPortMappings {
+		existingSwitch, err := libovsdbops.FindLogicalSwitchByName(nbClient, sw)
+		if err != nil {
+			return err
+		}
+		// SetLogicalSwitchIPPortMappingsOps replaces ip_port_mappings
+		// wholesale, but this switch may carry entries owned by other
+		// EnsureLBs callers (e.g. another service's health check on the
+		// same switch). Merge onto the switch's current mappings instead
+		// of overwriting them outright, so this call only ever adds or
+		// updates its own keys.
+		merged := map[string]string{}
+		if existingSwitch != nil {
+			for port, val := range existingSwitch.IPPortMappings {
+				merged[port] = val
+			}
+		}
+		for port, val := range mappings {
+			merged[port] = val
+		}
+		ops, err = libovsdbops.SetLogicalSwitchIPPortMappingsOps(nbClient, ops, getSwitch(sw), merged)
+		if err != nil {
+			return err
+		}
+	}
+
 	_, err = libovsdbops.TransactAndCheck(nbClient, ops)
 	if err != nil {
 		return err
@@ -215,8 +367,8 @@ func LoadBalancersEqualNoUUID(lbs1, lbs2 []LB) bool {
 	if len(lbs1) != len(lbs2) {
 		return false
 	}
-	new1 := make([]LB, len(lbs1))
-	new2 := make([]LB, len(lbs2))
+	new1 := make([]LB, 0, len(lbs1))
+	new2 := make([]LB, 0, len(lbs2))
 	for _, lb := range lbs1 {
 		lb.UUID = ""
 		new1 = append(new1, lb)
@@ -260,15 +412,14 @@ func buildLB(lb *LB) *nbdb.LoadBalancer {
 		"skip_snat": skipSNAT,
 	}
 
-	// Session affinity
-	// If enabled, then bucket flows by 3-tuple (proto, srcip, dstip)
-	// otherwise, use default ovn value
-	selectionFields := []nbdb.LoadBalancerSelectionFields{}
-	if lb.Opts.Affinity {
-		selectionFields = []string{
-			nbdb.LoadBalancerSelectionFieldsIPSrc,
-			nbdb.LoadBalancerSelectionFieldsIPDst,
-		}
+	// Session affinity: bucket flows by whichever combination of
+	// srcip/dstip/srcport/dstport the requested AffinityMode calls
+	// for, and carry the configured timeout along with it. No
+	// selection_fields/affinity_timeout are set for AffinityModeNone, which
+	// leaves OVN's own per-connection default selection in place.
+	selectionFields := selectionFieldsForAffinityMode(affinityMode(lb.Opts))
+	if len(selectionFields) > 0 && lb.Opts.AffinityTimeout > 0 {
+		options["affinity_timeout"] = strconv.Itoa(int(lb.Opts.AffinityTimeout.Seconds()))
 	}
 
 	// vipMap
@@ -277,6 +428,138 @@ func buildLB(lb *LB) *nbdb.LoadBalancer {
 	return libovsdbops.BuildLoadBalancer(lb.Name, strings.ToLower(lb.Protocol), selectionFields, vips, options, lb.ExternalIDs)
 }
 
+// affinityMode resolves the effective AffinityMode for opts, honoring the
+// deprecated Affinity bool when AffinityMode wasn't set.
+func affinityMode(opts LBOpts) AffinityMode {
+	if opts.AffinityMode != AffinityModeNone {
+		return opts.AffinityMode
+	}
+	if opts.Affinity {
+		return AffinityModeClientIP
+	}
+	return AffinityModeNone
+}
+
+// selectionFieldsForAffinityMode translates an AffinityMode into the OVN
+// selection_fields that implement it.
+func selectionFieldsForAffinityMode(mode AffinityMode) []nbdb.LoadBalancerSelectionFields {
+	switch mode {
+	case AffinityModeClientIP:
+		return []nbdb.LoadBalancerSelectionFields{
+			nbdb.LoadBalancerSelectionFieldsIPSrc,
+			nbdb.LoadBalancerSelectionFieldsIPDst,
+		}
+	case AffinityModeClientIPPort:
+		return []nbdb.LoadBalancerSelectionFields{
+			nbdb.LoadBalancerSelectionFieldsIPSrc,
+			nbdb.LoadBalancerSelectionFieldsIPDst,
+			nbdb.LoadBalancerSelectionFieldsTPSrc,
+			nbdb.LoadBalancerSelectionFieldsTPDst,
+		}
+	case AffinityModeProto:
+		return []nbdb.LoadBalancerSelectionFields{
+			nbdb.LoadBalancerSelectionFieldsTPSrc,
+			nbdb.LoadBalancerSelectionFieldsTPDst,
+		}
+	default:
+		return nil
+	}
+}
+
+// buildHealthCheck builds the nbdb.LoadBalancerHealthCheck for lb, assuming
+// lb.Opts.HealthCheck is set
+func buildHealthCheck(lb *LB) *nbdb.LoadBalancerHealthCheck {
+	hc := lb.Opts.HealthCheck
+	return &nbdb.LoadBalancerHealthCheck{
+		Vip: hc.Vip,
+		Options: map[string]string{
+			"interval":      strconv.Itoa(int(hc.Interval.Seconds())),
+			"timeout":       strconv.Itoa(int(hc.Timeout.Seconds())),
+			"success_count": strconv.Itoa(hc.SuccessCount),
+			"failure_count": strconv.Itoa(hc.FailureCount),
+		},
+		ExternalIDs: lb.ExternalIDs,
+	}
+}
+
+// meterName returns the name of the rate-limit Meter owned by lb. Meters are
+// referenced from a Load_Balancer row by name (options["rate_limit_meter"]),
+// not by UUID, so this is computed deterministically rather than looked up.
+func meterName(lbName string) string {
+	return lbName + "_ratelimit"
+}
+
+// effectiveLimits returns the connection caps that should be enforced for
+// lb: the LB-level limits from lb.Opts, widened to the highest (least
+// strict) value requested by any of its rules. OVN only supports a single
+// rate_limit_meter per Load_Balancer row, so per-rule overrides can't be
+// expressed independently of one another; when a rule's own limit is lower
+// than what another rule (or LBOpts) on the same LB sets, it gets coalesced
+// up to that higher value instead, and this logs when that happens so the
+// mismatch isn't silent.
+func effectiveLimits(lb *LB) (maxConnections, maxConnectionsPerSecond int) {
+	maxConnections = lb.Opts.MaxConnections
+	maxConnectionsPerSecond = lb.Opts.MaxConnectionsPerSecond
+	for _, rule := range lb.Rules {
+		if rule.MaxConnections > maxConnections {
+			maxConnections = rule.MaxConnections
+		}
+		if rule.MaxConnectionsPerSecond > maxConnectionsPerSecond {
+			maxConnectionsPerSecond = rule.MaxConnectionsPerSecond
+		}
+	}
+
+	for _, rule := range lb.Rules {
+		if (rule.MaxConnections > 0 && rule.MaxConnections != maxConnections) ||
+			(rule.MaxConnectionsPerSecond > 0 && rule.MaxConnectionsPerSecond != maxConnectionsPerSecond) {
+			klog.Warningf(
+				"LB %s: rule %s requested MaxConnections=%d MaxConnectionsPerSecond=%d but OVN only supports "+
+					"one rate_limit_meter per LB; coalescing to the highest LB-wide limit of MaxConnections=%d MaxConnectionsPerSecond=%d",
+				lb.Name, rule.Source, rule.MaxConnections, rule.MaxConnectionsPerSecond, maxConnections, maxConnectionsPerSecond)
+		}
+	}
+
+	return
+}
+
+// buildMeter builds the nbdb.Meter and its single nbdb.MeterBand that
+// enforce maxConnections/maxConnectionsPerSecond for lb. Callers must only
+// invoke this when at least one of the two is non-zero.
+//
+// OVN meters rate-limit packets, they have no notion of concurrent
+// connections, so maxConnections is approximated as a packet-rate ceiling
+// when no explicit per-second rate was requested, and is otherwise just
+// recorded in the meter's ExternalIDs for observability.
+func buildMeter(lb *LB, maxConnections, maxConnectionsPerSecond int) (*nbdb.Meter, *nbdb.MeterBand) {
+	rate := maxConnectionsPerSecond
+	if rate == 0 {
+		rate = maxConnections
+	}
+
+	band := &nbdb.MeterBand{
+		Action:      nbdb.MeterBandActionDrop,
+		Rate:        rate,
+		ExternalIDs: lb.ExternalIDs,
+	}
+
+	externalIDs := lb.ExternalIDs
+	if maxConnections > 0 {
+		externalIDs = make(map[string]string, len(lb.ExternalIDs)+1)
+		for k, v := range lb.ExternalIDs {
+			externalIDs[k] = v
+		}
+		externalIDs["max_connections"] = strconv.Itoa(maxConnections)
+	}
+
+	meter := &nbdb.Meter{
+		Name:        meterName(lb.Name),
+		Unit:        nbdb.MeterUnitPktps,
+		ExternalIDs: externalIDs,
+	}
+
+	return meter, band
+}
+
 // buildVipMap returns a viups map from a set of rules
 func buildVipMap(rules []LBRule) map[string]string {
 	vipMap := make(map[string]string, len(rules))
@@ -436,3 +719,82 @@ func listGroups(nbClient libovsdbclient.Client) (lbToGroups map[string]sets.Stri
 
 	return
 }
+
+// listHealthChecks builds the lb UUID -> health check mapping for the
+// health checks referenced by the given (already owned) load balancers
+func listHealthChecks(nbClient libovsdbclient.Client, owned []nbdb.LoadBalancer) (map[string]*nbdb.LoadBalancerHealthCheck, error) {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished listHealthChecks: %v", time.Since(startTime))
+	}()
+
+	all, err := libovsdbops.ListLoadBalancerHealthChecks(nbClient)
+	if err != nil {
+		return nil, err
+	}
+	byUUID := make(map[string]*nbdb.LoadBalancerHealthCheck, len(all))
+	for _, hc := range all {
+		byUUID[hc.UUID] = hc
+	}
+
+	lbToHealthCheck := map[string]*nbdb.LoadBalancerHealthCheck{}
+	for _, lb := range owned {
+		if len(lb.HealthCheck) == 0 {
+			continue
+		}
+		if hc, ok := byUUID[lb.HealthCheck[0]]; ok {
+			lbToHealthCheck[lb.UUID] = hc
+		}
+	}
+
+	return lbToHealthCheck, nil
+}
+
+// listMeters builds the lb UUID -> rate-limit meter mapping, along with the
+// mapping from each of those meters' UUID to its (sole) Meter_Band, for the
+// rate_limit_meter referenced by the given (already owned) load balancers
+func listMeters(nbClient libovsdbclient.Client, owned []nbdb.LoadBalancer) (map[string]*nbdb.Meter, map[string]*nbdb.MeterBand, error) {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished listMeters: %v", time.Since(startTime))
+	}()
+
+	allMeters, err := libovsdbops.ListMeters(nbClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	metersByName := make(map[string]*nbdb.Meter, len(allMeters))
+	for _, m := range allMeters {
+		metersByName[m.Name] = m
+	}
+
+	allBands, err := libovsdbops.ListMeterBands(nbClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	bandsByUUID := make(map[string]*nbdb.MeterBand, len(allBands))
+	for _, b := range allBands {
+		bandsByUUID[b.UUID] = b
+	}
+
+	lbToMeter := map[string]*nbdb.Meter{}
+	meterToBand := map[string]*nbdb.MeterBand{}
+	for _, lb := range owned {
+		name := lb.Options["rate_limit_meter"]
+		if name == "" {
+			continue
+		}
+		meter, ok := metersByName[name]
+		if !ok {
+			continue
+		}
+		lbToMeter[lb.UUID] = meter
+		if len(meter.Bands) > 0 {
+			if band, ok := bandsByUUID[meter.Bands[0]]; ok {
+				meterToBand[meter.UUID] = band
+			}
+		}
+	}
+
+	return lbToMeter, meterToBand, nil
+}
@@ -0,0 +1,112 @@
+package loadbalancer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ovn-org/ovn-kubernetes/go-controller/pkg/nbdb"
+)
+
+// TestBuildLBAffinity covers the ClientIP -> None affinity transition at
+// the buildLB level. Asserting the same transition against EnsureLBs's
+// actual transaction output against a fake OVSDB client isn't possible in
+// this tree yet: EnsureLBs and every libovsdbops helper it calls build on
+// OperationModel/ModelClient (NewModelClient, CreateOrUpdateOps,
+// TransactAndCheck, ...), and model_client.go -- the file that would
+// implement them -- isn't present here, in baseline or after (see the
+// sb_global.go and errors.go commits, which hit the same gap). There's
+// nothing to fake a client against until that lands.
+func TestBuildLBAffinity(t *testing.T) {
+	base := LB{
+		Name:     "test-lb",
+		Protocol: "TCP",
+	}
+
+	clientIP := base
+	clientIP.Opts = LBOpts{AffinityMode: AffinityModeClientIP, AffinityTimeout: 30 * time.Second}
+	blb := buildLB(&clientIP)
+	if len(blb.SelectionFields) != 2 {
+		t.Fatalf("expected 2 selection fields for ClientIP affinity, got %v", blb.SelectionFields)
+	}
+	if blb.Options["affinity_timeout"] != "30" {
+		t.Fatalf("expected affinity_timeout 30, got %q", blb.Options["affinity_timeout"])
+	}
+
+	// flipping back to None mid-flight must strip both the selection
+	// fields and the timeout
+	none := base
+	none.Opts = LBOpts{AffinityMode: AffinityModeNone}
+	blb = buildLB(&none)
+	if len(blb.SelectionFields) != 0 {
+		t.Fatalf("expected no selection fields for None affinity, got %v", blb.SelectionFields)
+	}
+	if _, ok := blb.Options["affinity_timeout"]; ok {
+		t.Fatalf("expected no affinity_timeout option for None affinity, got %q", blb.Options["affinity_timeout"])
+	}
+}
+
+func TestBuildLBAffinityBackwardsCompat(t *testing.T) {
+	lb := LB{Name: "test-lb", Protocol: "TCP", Opts: LBOpts{Affinity: true}}
+	blb := buildLB(&lb)
+	if len(blb.SelectionFields) != 2 {
+		t.Fatalf("expected legacy Affinity=true to behave like ClientIP, got %v", blb.SelectionFields)
+	}
+}
+
+func TestEffectiveLimits(t *testing.T) {
+	lb := LB{
+		Name: "test-lb",
+		Opts: LBOpts{MaxConnections: 100, MaxConnectionsPerSecond: 10},
+		Rules: []LBRule{
+			{MaxConnectionsPerSecond: 25},
+			{MaxConnections: 50},
+		},
+	}
+
+	maxConn, maxConnPerSec := effectiveLimits(&lb)
+	if maxConn != 100 {
+		t.Fatalf("expected LB-level MaxConnections 100 to win, got %d", maxConn)
+	}
+	if maxConnPerSec != 25 {
+		t.Fatalf("expected rule override MaxConnectionsPerSecond 25 to win, got %d", maxConnPerSec)
+	}
+}
+
+func TestBuildMeter(t *testing.T) {
+	lb := LB{Name: "test-lb", ExternalIDs: map[string]string{"k8s.ovn.org/owner": "test"}}
+
+	meter, band := buildMeter(&lb, 0, 50)
+	if meter.Name != "test-lb_ratelimit" {
+		t.Fatalf("unexpected meter name %q", meter.Name)
+	}
+	if meter.Unit != nbdb.MeterUnitPktps {
+		t.Fatalf("expected pktps unit, got %q", meter.Unit)
+	}
+	if band.Rate != 50 {
+		t.Fatalf("expected band rate 50, got %d", band.Rate)
+	}
+	if _, ok := meter.ExternalIDs["max_connections"]; ok {
+		t.Fatalf("did not expect max_connections to be recorded when unset")
+	}
+
+	meter, band = buildMeter(&lb, 200, 0)
+	if band.Rate != 200 {
+		t.Fatalf("expected MaxConnections to be used as the rate when no per-second rate is set, got %d", band.Rate)
+	}
+	if meter.ExternalIDs["max_connections"] != "200" {
+		t.Fatalf("expected max_connections to be recorded, got %q", meter.ExternalIDs["max_connections"])
+	}
+}
+
+func TestLoadBalancersEqualNoUUID(t *testing.T) {
+	lb1 := LB{Name: "a", UUID: "uuid-1"}
+	lb2 := LB{Name: "a", UUID: "uuid-2"}
+	if !LoadBalancersEqualNoUUID([]LB{lb1}, []LB{lb2}) {
+		t.Fatalf("expected LBs differing only in UUID to be equal")
+	}
+
+	lb3 := LB{Name: "b", UUID: "uuid-1"}
+	if LoadBalancersEqualNoUUID([]LB{lb1}, []LB{lb3}) {
+		t.Fatalf("expected LBs with different names to be unequal")
+	}
+}
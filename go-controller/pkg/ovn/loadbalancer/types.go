@@ -0,0 +1,137 @@
+package loadbalancer
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// LB is the desired configuration of an OVN load balancer, independent of
+// how it is represented on the wire (nbdb.LoadBalancer) or of what it is
+// attached to.
+type LB struct {
+	Name        string
+	UUID        string
+	Protocol    string // one of TCP, UDP, SCTP
+	ExternalIDs map[string]string
+	Opts        LBOpts
+	Rules       []LBRule
+
+	// Switches, Routers, and Groups are the logical switches, routers, and
+	// load balancer groups this LB should be attached to
+	Switches []string
+	Routers  []string
+	Groups   []string
+}
+
+// LBOpts are the options that control how OVN load-balances and tracks
+// connections for a given LB
+type LBOpts struct {
+	// Unidling, if set, configures the LB to emit an empty_lb event instead
+	// of rejecting connections to VIPs with no backends
+	Unidling bool
+
+	// SkipSNAT, if set, configures the LB to not SNAT traffic sent to a
+	// backend
+	SkipSNAT bool
+
+	// Affinity, if set, enables session affinity bucketing flows by source
+	// and destination IP.
+	//
+	// Deprecated: use AffinityMode instead, which can also express
+	// IP-and-port and port-only affinity. Affinity is equivalent to
+	// AffinityMode set to AffinityModeClientIP.
+	Affinity bool
+
+	// AffinityMode selects which OVN selection_fields are used to bucket
+	// flows for session affinity. Defaults to AffinityModeNone.
+	AffinityMode AffinityMode
+
+	// AffinityTimeout is how long a session affinity binding is kept after
+	// its last use. Only meaningful when AffinityMode is not
+	// AffinityModeNone. Zero means OVN's own default.
+	AffinityTimeout time.Duration
+
+	// HealthCheck, if set, configures OVN to actively probe backends and
+	// stop directing traffic to the ones that are failing
+	HealthCheck *LBHealthCheck
+
+	// MaxConnections caps the number of concurrent connections OVN will
+	// admit towards this LB's backends. Zero means unlimited.
+	MaxConnections int
+
+	// MaxConnectionsPerSecond caps the rate of new connections OVN will
+	// admit towards this LB's backends. Zero means unlimited.
+	MaxConnectionsPerSecond int
+}
+
+// AffinityMode selects which fields OVN uses to bucket flows for session
+// affinity, mirroring Service.spec.sessionAffinity and the finer grained
+// options OVN itself exposes through selection_fields.
+type AffinityMode string
+
+const (
+	// AffinityModeNone disables session affinity; OVN picks a backend per
+	// connection using its default selection algorithm.
+	AffinityModeNone AffinityMode = ""
+	// AffinityModeClientIP buckets flows by source and destination IP.
+	AffinityModeClientIP AffinityMode = "ClientIP"
+	// AffinityModeClientIPPort buckets flows by source/destination IP and
+	// port.
+	AffinityModeClientIPPort AffinityMode = "ClientIPPort"
+	// AffinityModeProto buckets flows by source/destination port only
+	// (selectionFieldsForAffinityMode sets TPSrc/TPDst, not an IP or
+	// protocol field -- OVN's selection_fields has no protocol field to
+	// select on).
+	AffinityModeProto AffinityMode = "Proto"
+)
+
+// LBHealthCheck configures OVN's active health checking of an LB's backends
+type LBHealthCheck struct {
+	// Vip is the VIP whose backends should be health checked
+	Vip string
+
+	Interval     time.Duration
+	Timeout      time.Duration
+	SuccessCount int
+	FailureCount int
+
+	// SwitchPortMappings holds, per logical switch this LB's backends live
+	// on, the ip_port_mappings entries (logical switch port UUID -> "mac
+	// ip") OVN needs in order to source health probes towards those
+	// backends. Callers are expected to have already resolved each
+	// backend's owning logical switch port; EnsureLBs only programs what it
+	// is given here.
+	SwitchPortMappings map[string]map[string]string
+}
+
+// LBRule is a VIP and its associated backends
+type LBRule struct {
+	Source  Addr
+	Targets []Addr
+
+	// MaxConnections and MaxConnectionsPerSecond are best-effort,
+	// currently-coalesced per-VIP caps: OVN only supports a single
+	// rate_limit_meter per Load_Balancer row, so these are NOT enforced
+	// independently per rule. EnsureLBs widens the LB's single meter to
+	// the highest (least strict) value set across LBOpts and every rule, and
+	// logs when that widening changes what a rule actually gets enforced
+	// at. A rule whose own limit is lower than a sibling rule's will be
+	// enforced at the sibling's (higher) limit, not its own.
+	MaxConnections          int
+	MaxConnectionsPerSecond int
+}
+
+// Addr is an IP + port pair. Port may be 0 when the rule applies to all
+// ports of a protocol-less VIP.
+type Addr struct {
+	IP   net.IP
+	Port int32
+}
+
+func (a Addr) String() string {
+	if a.Port == 0 {
+		return a.IP.String()
+	}
+	return net.JoinHostPort(a.IP.String(), fmt.Sprintf("%d", a.Port))
+}
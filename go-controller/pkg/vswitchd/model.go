@@ -0,0 +1,31 @@
+// Code generated by "libovsdb.modelgen"
+// DO NOT EDIT.
+
+package vswitchd
+
+import "github.com/ovn-org/libovsdb/model"
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FullDatabaseModel returns the DatabaseModel object to be used in libovsdb
+func FullDatabaseModel() (model.ClientDBModel, error) {
+	return model.NewClientDBModel("Open_vSwitch", map[string]model.Model{
+		"Open_vSwitch": &OpenvSwitch{},
+		"Bridge":       &Bridge{},
+		"Port":         &Port{},
+		"Interface":    &Interface{},
+		"Controller":   &Controller{},
+		"QoS":          &QoS{},
+		"Queue":        &Queue{},
+	})
+}